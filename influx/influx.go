@@ -0,0 +1,186 @@
+// Package influx periodically flushes decoded Dexcom records to an InfluxDB
+// endpoint using the line protocol.
+package influx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mpetka/dexcom"
+)
+
+// Reporter batches points built from *dexcom.Record values and flushes them
+// to InfluxDB on a fixed interval, similar in shape to a ResettingTimer-style
+// metrics reporter.
+type Reporter struct {
+	url      string
+	db       string
+	interval time.Duration
+	window   time.Duration
+	client   *http.Client
+
+	mu     sync.Mutex
+	points []point
+}
+
+type point struct {
+	line string
+	t    time.Time
+}
+
+// NewReporter creates a Reporter that writes to the InfluxDB database db at
+// url every interval. Points older than window are dropped rather than
+// written, so a slow consumer does not build up unbounded history.
+func NewReporter(url, db string, interval, window time.Duration) *Reporter {
+	return &Reporter{
+		url:      url,
+		db:       db,
+		interval: interval,
+		window:   window,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Add converts r into one or more line-protocol points and queues them for
+// the next flush.
+func (rep *Reporter) Add(r *dexcom.Record) {
+	lines := lineProtocol(r)
+	if len(lines) == 0 {
+		return
+	}
+	now := r.Time()
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	for _, line := range lines {
+		rep.points = append(rep.points, point{line: line, t: now})
+	}
+}
+
+// Report consumes records until ctx is canceled, records is closed, or a
+// flush fails, flushing the accumulated batch to InfluxDB every interval.
+func (rep *Reporter) Report(ctx context.Context, records <-chan *dexcom.Record) error {
+	ticker := time.NewTicker(rep.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), rep.client.Timeout)
+			err := rep.flush(flushCtx)
+			cancel()
+			if err != nil {
+				return err
+			}
+			return ctx.Err()
+		case r, ok := <-records:
+			if !ok {
+				return rep.flush(ctx)
+			}
+			rep.Add(r)
+		case <-ticker.C:
+			if err := rep.flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (rep *Reporter) flush(ctx context.Context) error {
+	rep.mu.Lock()
+	cutoff := time.Now().Add(-rep.window)
+	kept := rep.points[:0]
+	for _, p := range rep.points {
+		if rep.window > 0 && p.t.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	batch := make([]string, len(kept))
+	for i, p := range kept {
+		batch[i] = p.line
+	}
+	rep.points = nil
+	rep.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return rep.write(ctx, batch)
+}
+
+func (rep *Reporter) write(ctx context.Context, lines []string) error {
+	body := strings.Join(lines, "\n")
+	const maxAttempts = 3
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, rep.url+"/write?db="+rep.db, bytes.NewBufferString(body))
+		if reqErr != nil {
+			return reqErr
+		}
+		var resp *http.Response
+		resp, err = rep.client.Do(req)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 500 {
+			if resp.StatusCode >= 300 {
+				return fmt.Errorf("influx: write to %s failed with status %d", rep.url, resp.StatusCode)
+			}
+			return nil
+		}
+		err = fmt.Errorf("influx: write to %s failed with status %d", rep.url, resp.StatusCode)
+		time.Sleep(time.Duration(attempt+1) * 500 * time.Millisecond)
+	}
+	return err
+}
+
+// lineProtocol converts a single Record into one line-protocol point for
+// each non-nil EGV, Sensor, Calibration and Insertion sub-struct, plus one
+// calibration_data point per CalibrationData entry. Meter and XML are not
+// written.
+func lineProtocol(r *dexcom.Record) []string {
+	var lines []string
+	ts := r.Time().UnixNano()
+
+	if egv := r.EGV; egv != nil {
+		lines = append(lines, fmt.Sprintf(
+			"egv,display_only=%t glucose=%di,noise=%di,trend=%di %d",
+			egv.DisplayOnly, egv.Glucose, egv.Noise, egv.Trend, ts,
+		))
+	}
+	if sensor := r.Sensor; sensor != nil {
+		lines = append(lines, fmt.Sprintf(
+			"sensor unfiltered=%di,filtered=%di,rssi=%di %d",
+			sensor.Unfiltered, sensor.Filtered, sensor.RSSI, ts,
+		))
+	}
+	if cal := r.Calibration; cal != nil {
+		lines = append(lines, fmt.Sprintf(
+			"calibration slope=%s,intercept=%s,scale=%s,decay=%s %d",
+			strconv.FormatFloat(cal.Slope, 'f', -1, 64),
+			strconv.FormatFloat(cal.Intercept, 'f', -1, 64),
+			strconv.FormatFloat(cal.Scale, 'f', -1, 64),
+			strconv.FormatFloat(cal.Decay, 'f', -1, 64),
+			ts,
+		))
+		for _, d := range cal.Data {
+			lines = append(lines, fmt.Sprintf(
+				"calibration_data glucose=%di,raw=%di %d",
+				d.Glucose, d.Raw, d.TimeApplied.UnixNano(),
+			))
+		}
+	}
+	if ins := r.Insertion; ins != nil {
+		lines = append(lines, fmt.Sprintf(
+			"insertion,event=%s value=1i %d",
+			ins.Event.String(), ts,
+		))
+	}
+	return lines
+}
@@ -0,0 +1,72 @@
+package influx
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mpetka/dexcom"
+)
+
+func TestLineProtocol(t *testing.T) {
+	when := time.Unix(1700000000, 0).UTC()
+	r := &dexcom.Record{
+		Timestamp: dexcom.Timestamp{DisplayTime: when},
+		EGV: &dexcom.EGVInfo{
+			Glucose:     120,
+			DisplayOnly: true,
+			Noise:       1,
+			Trend:       dexcom.FLAT,
+		},
+		Sensor: &dexcom.SensorInfo{
+			Unfiltered: 1000,
+			Filtered:   990,
+			RSSI:       -60,
+		},
+		Calibration: &dexcom.CalibrationInfo{
+			Slope:     1.1,
+			Intercept: 2.2,
+			Scale:     1,
+			Decay:     0.01,
+			Data: []dexcom.CalibrationData{
+				{Glucose: 100, Raw: 95, TimeApplied: when},
+			},
+		},
+		Insertion: &dexcom.InsertionInfo{Event: dexcom.Started},
+	}
+
+	lines := lineProtocol(r)
+
+	cases := []struct {
+		prefix string
+		want   string
+	}{
+		{"egv", "egv,display_only=true glucose=120i,noise=1i,trend=4i"},
+		{"sensor", "sensor unfiltered=1000i,filtered=990i,rssi=-60i"},
+		{"calibration ", "calibration slope=1.1,intercept=2.2,scale=1,decay=0.01"},
+		{"calibration_data", "calibration_data glucose=100i,raw=95i"},
+		{"insertion", "insertion,event=Started value=1i"},
+	}
+	for _, c := range cases {
+		found := false
+		for _, line := range lines {
+			if strings.HasPrefix(line, c.want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("lineProtocol missing line with prefix %q, got: %v", c.want, lines)
+		}
+	}
+
+	ts := strconv.FormatInt(when.UnixNano(), 10)
+	for _, line := range lines {
+		if strings.HasPrefix(line, "egv") || strings.HasPrefix(line, "sensor") || strings.HasPrefix(line, "calibration ") || strings.HasPrefix(line, "insertion") {
+			if !strings.HasSuffix(line, " "+ts) {
+				t.Errorf("line %q does not end with timestamp %s", line, ts)
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package prom exposes live CGM readings decoded from a Dexcom receiver as
+// Prometheus metrics, suitable for a drop-in /metrics endpoint.
+package prom
+
+import (
+	"context"
+
+	"github.com/mpetka/dexcom"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "dexcom"
+
+// Collector translates a stream of *dexcom.Record values into Prometheus
+// gauges and counters. It does not implement prometheus.Collector directly;
+// instead its metrics are ordinary collectors that should be registered once
+// via MustRegister and then kept up to date by feeding records through
+// Handle or Run.
+type Collector struct {
+	glucose      prometheus.Gauge
+	trend        *prometheus.GaugeVec
+	noise        prometheus.Gauge
+	unfiltered   prometheus.Gauge
+	filtered     prometheus.Gauge
+	rssi         prometheus.Gauge
+	calSlope     prometheus.Gauge
+	calIntercept prometheus.Gauge
+	calDecay     prometheus.Gauge
+	insertions   *prometheus.CounterVec
+	exceptions   *prometheus.CounterVec
+	sensorOK     prometheus.Gauge
+	lastEGV      prometheus.Gauge
+}
+
+// NewCollector creates a Collector with all metrics initialized to zero
+// values. Call MustRegister to expose them on a registry.
+func NewCollector() *Collector {
+	return &Collector{
+		glucose: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "glucose_mg_dl",
+			Help:      "Most recent EGV glucose reading, in mg/dL.",
+		}),
+		trend: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "trend",
+			Help:      "Most recent trend arrow, one gauge per Trend set to 1.",
+		}, []string{"trend"}),
+		noise: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "noise",
+			Help:      "Most recent EGV noise level.",
+		}),
+		unfiltered: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "sensor_unfiltered",
+			Help:      "Most recent raw unfiltered sensor reading.",
+		}),
+		filtered: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "sensor_filtered",
+			Help:      "Most recent raw filtered sensor reading.",
+		}),
+		rssi: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "sensor_rssi",
+			Help:      "Most recent sensor RSSI.",
+		}),
+		calSlope: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "calibration_slope",
+			Help:      "Most recent calibration slope.",
+		}),
+		calIntercept: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "calibration_intercept",
+			Help:      "Most recent calibration intercept.",
+		}),
+		calDecay: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "calibration_decay",
+			Help:      "Most recent calibration decay.",
+		}),
+		insertions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "insertion_events_total",
+			Help:      "Count of sensor insertion events, by SensorChange.",
+		}, []string{"event"}),
+		exceptions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "special_glucose_total",
+			Help:      "Count of EGV records carrying a SpecialGlucose exception code.",
+		}, []string{"code"}),
+		sensorOK: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "sensor_ok",
+			Help:      "1 if the most recent EGV record carried a normal glucose value, 0 if it carried a SpecialGlucose exception code.",
+		}),
+		lastEGV: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_egv_timestamp_seconds",
+			Help:      "Unix timestamp of the most recently handled EGV record, so staleness of glucose_mg_dl can be alerted on.",
+		}),
+	}
+}
+
+// MustRegister registers every metric on reg, panicking on failure.
+func (c *Collector) MustRegister(reg prometheus.Registerer) {
+	reg.MustRegister(
+		c.glucose,
+		c.trend,
+		c.noise,
+		c.unfiltered,
+		c.filtered,
+		c.rssi,
+		c.calSlope,
+		c.calIntercept,
+		c.calDecay,
+		c.insertions,
+		c.exceptions,
+		c.sensorOK,
+		c.lastEGV,
+	)
+}
+
+// Handle updates the collector's metrics from a single decoded record.
+func (c *Collector) Handle(r *dexcom.Record) {
+	if egv := r.EGV; egv != nil {
+		c.lastEGV.Set(float64(r.Time().Unix()))
+		if dexcom.IsSpecial(egv.Glucose) {
+			c.sensorOK.Set(0)
+			c.exceptions.WithLabelValues(dexcom.SpecialGlucose(egv.Glucose).String()).Inc()
+		} else {
+			c.sensorOK.Set(1)
+			c.glucose.Set(float64(egv.Glucose))
+			c.trend.Reset()
+			c.trend.WithLabelValues(egv.Trend.String()).Set(1)
+			c.noise.Set(float64(egv.Noise))
+		}
+	}
+	if sensor := r.Sensor; sensor != nil {
+		c.unfiltered.Set(float64(sensor.Unfiltered))
+		c.filtered.Set(float64(sensor.Filtered))
+		c.rssi.Set(float64(sensor.RSSI))
+	}
+	if cal := r.Calibration; cal != nil {
+		c.calSlope.Set(cal.Slope)
+		c.calIntercept.Set(cal.Intercept)
+		c.calDecay.Set(cal.Decay)
+	}
+	if ins := r.Insertion; ins != nil {
+		c.insertions.WithLabelValues(ins.Event.String()).Inc()
+	}
+}
+
+// Run feeds every record received on records into Handle until records is
+// closed or ctx is canceled.
+func (c *Collector) Run(ctx context.Context, records <-chan *dexcom.Record) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case r, ok := <-records:
+			if !ok {
+				return
+			}
+			c.Handle(r)
+		}
+	}
+}
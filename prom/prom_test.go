@@ -0,0 +1,63 @@
+package prom
+
+import (
+	"testing"
+
+	"github.com/mpetka/dexcom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollectorHandleEGV(t *testing.T) {
+	c := NewCollector()
+	c.Handle(&dexcom.Record{EGV: &dexcom.EGVInfo{Glucose: 120, Noise: 2, Trend: dexcom.FLAT}})
+
+	if got := testutil.ToFloat64(c.glucose); got != 120 {
+		t.Errorf("glucose == %v, want 120", got)
+	}
+	if got := testutil.ToFloat64(c.noise); got != 2 {
+		t.Errorf("noise == %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(c.sensorOK); got != 1 {
+		t.Errorf("sensorOK == %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(c.trend.WithLabelValues(dexcom.FLAT.String())); got != 1 {
+		t.Errorf("trend[FLAT] == %v, want 1", got)
+	}
+}
+
+func TestCollectorHandleSpecialGlucose(t *testing.T) {
+	c := NewCollector()
+	c.Handle(&dexcom.Record{EGV: &dexcom.EGVInfo{Glucose: 120, Trend: dexcom.FLAT}})
+	c.Handle(&dexcom.Record{EGV: &dexcom.EGVInfo{Glucose: uint16(dexcom.SENSOR_NOT_ACTIVE)}})
+
+	if got := testutil.ToFloat64(c.glucose); got != 120 {
+		t.Errorf("glucose == %v, want 120 (unchanged by the exception record)", got)
+	}
+	if got := testutil.ToFloat64(c.sensorOK); got != 0 {
+		t.Errorf("sensorOK == %v, want 0 after a SpecialGlucose record", got)
+	}
+	if got := testutil.ToFloat64(c.exceptions.WithLabelValues(dexcom.SENSOR_NOT_ACTIVE.String())); got != 1 {
+		t.Errorf("exceptions[SENSOR_NOT_ACTIVE] == %v, want 1", got)
+	}
+}
+
+func TestCollectorHandleInsertion(t *testing.T) {
+	c := NewCollector()
+	c.Handle(&dexcom.Record{Insertion: &dexcom.InsertionInfo{Event: dexcom.Started}})
+
+	if got := testutil.ToFloat64(c.insertions.WithLabelValues(dexcom.Started.String())); got != 1 {
+		t.Errorf("insertions[Started] == %v, want 1", got)
+	}
+}
+
+func TestCollectorMustRegister(t *testing.T) {
+	c := NewCollector()
+	reg := prometheus.NewRegistry()
+	c.MustRegister(reg)
+
+	const wantCollectors = 10
+	if got := testutil.CollectAndCount(reg); got < wantCollectors {
+		t.Errorf("CollectAndCount == %d, want at least %d", got, wantCollectors)
+	}
+}
@@ -0,0 +1,162 @@
+package dexcom
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MarshalJSON includes the record's resolved display time alongside its
+// fields so that downstream consumers (Nightscout uploaders, log pipelines)
+// don't need to re-derive it from Timestamp.
+func (r Record) MarshalJSON() ([]byte, error) {
+	type alias Record
+	return json.Marshal(struct {
+		Time time.Time `json:"Time"`
+		alias
+	}{Time: r.Time(), alias: alias(r)})
+}
+
+// UnmarshalJSON accepts the shape produced by MarshalJSON, ignoring the
+// derived Time field.
+func (r *Record) UnmarshalJSON(data []byte) error {
+	type alias Record
+	aux := struct {
+		Time time.Time `json:"Time"`
+		*alias
+	}{alias: (*alias)(r)}
+	return json.Unmarshal(data, &aux)
+}
+
+// MarshalJSON renders the Glucose field as a self-describing
+// {"special": "..."} object when it encodes a SpecialGlucose exception,
+// instead of a bare integer such as 5.
+func (e EGVInfo) MarshalJSON() ([]byte, error) {
+	type alias EGVInfo
+	if IsSpecial(e.Glucose) {
+		return json.Marshal(struct {
+			Glucose SpecialGlucose `json:"Glucose"`
+			alias
+		}{Glucose: SpecialGlucose(e.Glucose), alias: alias(e)})
+	}
+	return json.Marshal(alias(e))
+}
+
+// UnmarshalJSON accepts Glucose as either a raw numeric value or the
+// {"special": "..."} form produced by MarshalJSON.
+func (e *EGVInfo) UnmarshalJSON(data []byte) error {
+	type alias EGVInfo
+	aux := struct {
+		Glucose json.RawMessage `json:"Glucose"`
+		*alias
+	}{alias: (*alias)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.Glucose) == 0 {
+		return nil
+	}
+	var g uint16
+	if err := json.Unmarshal(aux.Glucose, &g); err == nil {
+		e.Glucose = g
+		return nil
+	}
+	var special SpecialGlucose
+	if err := json.Unmarshal(aux.Glucose, &special); err != nil {
+		return fmt.Errorf("unmarshaling EGVInfo.Glucose: %w", err)
+	}
+	e.Glucose = uint16(special)
+	return nil
+}
+
+// MarshalJSON renders t as its name, display symbol, and numeric code, e.g.
+// {"name":"FLAT","symbol":"→","code":4}.
+func (t Trend) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name   string `json:"name"`
+		Symbol string `json:"symbol"`
+		Code   byte   `json:"code"`
+	}{Name: t.String(), Symbol: t.Symbol(), Code: byte(t)})
+}
+
+// UnmarshalJSON accepts either the object form produced by MarshalJSON or a
+// bare numeric code.
+func (t *Trend) UnmarshalJSON(data []byte) error {
+	var code byte
+	if err := json.Unmarshal(data, &code); err == nil {
+		*t = Trend(code)
+		return nil
+	}
+	var obj struct {
+		Code byte `json:"code"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("unmarshaling Trend: %w", err)
+	}
+	*t = Trend(obj.Code)
+	return nil
+}
+
+// MarshalJSON renders s as {"special": "..."} using its stringer name.
+func (s SpecialGlucose) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Special string `json:"special"`
+	}{Special: s.String()})
+}
+
+// UnmarshalJSON accepts either the object form produced by MarshalJSON or a
+// bare numeric code.
+func (s *SpecialGlucose) UnmarshalJSON(data []byte) error {
+	var code uint16
+	if err := json.Unmarshal(data, &code); err == nil {
+		*s = SpecialGlucose(code)
+		return nil
+	}
+	var obj struct {
+		Special string `json:"special"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("unmarshaling SpecialGlucose: %w", err)
+	}
+	for v := SpecialGlucose(1); v <= specialLimit; v++ {
+		if v.String() == obj.Special {
+			*s = v
+			return nil
+		}
+	}
+	// Codes in the special range without a name marshal as
+	// "SpecialGlucose(N)".
+	var n uint16
+	if _, err := fmt.Sscanf(obj.Special, "SpecialGlucose(%d)", &n); err == nil && n <= uint16(specialLimit) {
+		*s = SpecialGlucose(n)
+		return nil
+	}
+	return fmt.Errorf("unmarshaling SpecialGlucose: unknown value %q", obj.Special)
+}
+
+// MarshalJSON renders s as its stringer name.
+func (s SensorChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON accepts either a stringer name or a bare numeric code.
+func (s *SensorChange) UnmarshalJSON(data []byte) error {
+	var code byte
+	if err := json.Unmarshal(data, &code); err == nil {
+		*s = SensorChange(code)
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("unmarshaling SensorChange: %w", err)
+	}
+	switch name {
+	case Stopped.String():
+		*s = Stopped
+	case Started.String():
+		*s = Started
+	default:
+		return fmt.Errorf("unmarshaling SensorChange: unknown value %q", name)
+	}
+	return nil
+}
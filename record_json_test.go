@@ -0,0 +1,142 @@
+package dexcom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestEGVInfoJSONSpecial(t *testing.T) {
+	in := EGVInfo{Glucose: uint16(SENSOR_NOT_CALIBRATED), Noise: 1, Trend: FLAT}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out EGVInfo
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.Glucose != in.Glucose {
+		t.Errorf("Glucose round-tripped as %d, want %d", out.Glucose, in.Glucose)
+	}
+}
+
+func TestEGVInfoJSONRawNumber(t *testing.T) {
+	var out EGVInfo
+	if err := json.Unmarshal([]byte(`{"Glucose":120,"DisplayOnly":false,"Noise":0,"Trend":4}`), &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.Glucose != 120 {
+		t.Errorf("Glucose == %d, want 120", out.Glucose)
+	}
+}
+
+func TestTrendJSON(t *testing.T) {
+	data, err := json.Marshal(FLAT)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out Trend
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out != FLAT {
+		t.Errorf("Trend round-tripped as %v, want %v", out, FLAT)
+	}
+	var raw Trend
+	if err := json.Unmarshal([]byte("4"), &raw); err != nil {
+		t.Fatalf("Unmarshal raw: %v", err)
+	}
+	if raw != FLAT {
+		t.Errorf("raw Trend == %v, want %v", raw, FLAT)
+	}
+}
+
+func TestSpecialGlucoseJSON(t *testing.T) {
+	data, err := json.Marshal(SENSOR_NOT_ACTIVE)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out SpecialGlucose
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out != SENSOR_NOT_ACTIVE {
+		t.Errorf("SpecialGlucose round-tripped as %v, want %v", out, SENSOR_NOT_ACTIVE)
+	}
+
+	var raw SpecialGlucose
+	if err := json.Unmarshal([]byte("3"), &raw); err != nil {
+		t.Fatalf("Unmarshal raw: %v", err)
+	}
+	if raw != NO_ANTENNA {
+		t.Errorf("raw SpecialGlucose == %v, want %v", raw, NO_ANTENNA)
+	}
+}
+
+func TestSpecialGlucoseJSONUnknownValue(t *testing.T) {
+	var out SpecialGlucose
+	err := json.Unmarshal([]byte(`{"special":"NOT_A_REAL_CODE"}`), &out)
+	if err == nil {
+		t.Fatal("Unmarshal of an unknown special code should fail, got nil error")
+	}
+}
+
+func TestSensorChangeJSON(t *testing.T) {
+	data, err := json.Marshal(Started)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out SensorChange
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out != Started {
+		t.Errorf("SensorChange round-tripped as %v, want %v", out, Started)
+	}
+
+	var raw SensorChange
+	if err := json.Unmarshal([]byte("1"), &raw); err != nil {
+		t.Fatalf("Unmarshal raw: %v", err)
+	}
+	if raw != Stopped {
+		t.Errorf("raw SensorChange == %v, want %v", raw, Stopped)
+	}
+}
+
+func TestSensorChangeJSONUnknownValue(t *testing.T) {
+	var out SensorChange
+	err := json.Unmarshal([]byte(`"NotARealEvent"`), &out)
+	if err == nil {
+		t.Fatal("Unmarshal of an unknown SensorChange name should fail, got nil error")
+	}
+}
+
+func TestRecordJSON(t *testing.T) {
+	display := time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)
+	system := display.Add(-time.Hour)
+	in := Record{
+		Timestamp: Timestamp{DisplayTime: display, SystemTime: system},
+		EGV:       &EGVInfo{Glucose: 120, Noise: 1, Trend: FLAT},
+		Insertion: &InsertionInfo{SystemTime: system, Event: Started},
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var out Record
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !out.Time().Equal(in.Time()) {
+		t.Errorf("Time() round-tripped as %v, want %v", out.Time(), in.Time())
+	}
+	if out.EGV == nil || out.EGV.Glucose != in.EGV.Glucose || out.EGV.Trend != in.EGV.Trend {
+		t.Errorf("EGV round-tripped as %+v, want %+v", out.EGV, in.EGV)
+	}
+	if out.Insertion == nil || out.Insertion.Event != in.Insertion.Event {
+		t.Errorf("Insertion round-tripped as %+v, want %+v", out.Insertion, in.Insertion)
+	}
+}
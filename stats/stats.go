@@ -0,0 +1,162 @@
+// Package stats maintains a low-memory streaming summary of EGV glucose
+// values, using the Greenwald-Khanna epsilon-approximate quantile algorithm
+// so that quantile queries do not require buffering the whole history.
+package stats
+
+import (
+	"context"
+
+	"github.com/mpetka/dexcom"
+)
+
+// DefaultEpsilon bounds the summary to roughly (1/epsilon)*log(epsilon*n)
+// tuples, which is small enough for weeks of 5-minute samples on-device.
+const DefaultEpsilon = 0.01
+
+// tuple is one entry of the Greenwald-Khanna summary: a value v, the rank
+// increment g since the previous tuple, and the maximum rank error delta.
+type tuple struct {
+	v     uint16
+	g     int
+	delta int
+}
+
+// Summary is a streaming epsilon-approximate quantile summary over EGV
+// glucose values. It is not safe for concurrent use.
+type Summary struct {
+	epsilon float64
+	n       int
+	tuples  []tuple
+}
+
+// NewSummary creates a Summary with the given approximation bound. Smaller
+// epsilon gives tighter quantile estimates at the cost of more memory.
+// epsilon must be in (0, 1]; an out-of-range value falls back to
+// DefaultEpsilon.
+func NewSummary(epsilon float64) *Summary {
+	if epsilon <= 0 || epsilon > 1 {
+		epsilon = DefaultEpsilon
+	}
+	return &Summary{epsilon: epsilon}
+}
+
+// compressThreshold returns floor(2*epsilon*n).
+func (s *Summary) compressThreshold() int {
+	return int(2 * s.epsilon * float64(s.n))
+}
+
+// Insert adds a single glucose value to the summary.
+func (s *Summary) Insert(v uint16) {
+	i := 0
+	for i < len(s.tuples) && s.tuples[i].v < v {
+		i++
+	}
+	t := tuple{v: v, g: 1}
+	if i == 0 || i == len(s.tuples) {
+		t.delta = 0
+	} else {
+		t.delta = s.compressThreshold()
+	}
+	s.tuples = append(s.tuples, tuple{})
+	copy(s.tuples[i+1:], s.tuples[i:])
+	s.tuples[i] = t
+	s.n++
+
+	if s.n%int(1/s.epsilon) == 0 {
+		s.compress()
+	}
+}
+
+// compress merges adjacent tuples whenever doing so cannot violate the
+// epsilon error bound.
+func (s *Summary) compress() {
+	band := s.compressThreshold()
+	for i := len(s.tuples) - 2; i >= 1; i-- {
+		if s.tuples[i].g+s.tuples[i+1].g+s.tuples[i+1].delta <= band {
+			s.tuples[i+1].g += s.tuples[i].g
+			s.tuples = append(s.tuples[:i], s.tuples[i+1:]...)
+		}
+	}
+}
+
+// Query returns the epsilon-approximate value at quantile q, where q is in
+// [0, 1].
+func (s *Summary) Query(q float64) uint16 {
+	if len(s.tuples) == 0 {
+		return 0
+	}
+	rank := q*float64(s.n) + float64(s.compressThreshold())/2
+	running := 0
+	prev := s.tuples[0].v
+	for _, t := range s.tuples {
+		running += t.g
+		if float64(running) > rank {
+			return prev
+		}
+		prev = t.v
+	}
+	return prev
+}
+
+// Median returns the approximate median glucose value.
+func (s *Summary) Median() uint16 {
+	return s.Query(0.5)
+}
+
+// IQR returns the approximate 25th and 75th percentile glucose values.
+func (s *Summary) IQR() (q25, q75 uint16) {
+	return s.Query(0.25), s.Query(0.75)
+}
+
+// P90 returns the approximate 90th percentile glucose value.
+func (s *Summary) P90() uint16 {
+	return s.Query(0.90)
+}
+
+// P95 returns the approximate 95th percentile glucose value.
+func (s *Summary) P95() uint16 {
+	return s.Query(0.95)
+}
+
+// TimeInRange returns the approximate fraction of observed glucose values
+// falling within [low, high], computed from the same summary rather than a
+// separate buffer.
+func (s *Summary) TimeInRange(low, high uint16) float64 {
+	if s.n == 0 {
+		return 0
+	}
+	inRange := 0
+	for _, t := range s.tuples {
+		if t.v >= low && t.v <= high {
+			inRange += t.g
+		}
+	}
+	return float64(inRange) / float64(s.n)
+}
+
+// Observe inserts a record's EGV glucose value, skipping any value that
+// encodes a SpecialGlucose exception so those codes don't pollute the
+// distribution.
+func (s *Summary) Observe(r *dexcom.Record) {
+	egv := r.EGV
+	if egv == nil || dexcom.IsSpecial(egv.Glucose) {
+		return
+	}
+	s.Insert(egv.Glucose)
+}
+
+// Run observes every record received on records until ctx is canceled or
+// records is closed.
+func (s *Summary) Run(ctx context.Context, records <-chan *dexcom.Record) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case r, ok := <-records:
+			if !ok {
+				return
+			}
+			s.Observe(r)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package stats
+
+import "testing"
+
+func TestSummaryQuery(t *testing.T) {
+	s := NewSummary(0.01)
+	for v := uint16(1); v <= 1000; v++ {
+		s.Insert(v)
+	}
+	cases := []struct {
+		q    float64
+		want uint16
+	}{
+		{0.5, 500},
+		{0.25, 250},
+		{0.75, 750},
+		{0.9, 900},
+	}
+	for _, c := range cases {
+		got := s.Query(c.q)
+		diff := int(got) - int(c.want)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > int(0.01*1000) {
+			t.Errorf("Query(%v) == %d, want near %d", c.q, got, c.want)
+		}
+	}
+}
+
+func TestNewSummaryClampsOutOfRangeEpsilon(t *testing.T) {
+	for _, epsilon := range []float64{0, -1, 1.5} {
+		s := NewSummary(epsilon)
+		if s.epsilon != DefaultEpsilon {
+			t.Errorf("NewSummary(%v).epsilon == %v, want DefaultEpsilon", epsilon, s.epsilon)
+		}
+		s.Insert(100) // must not panic with a divide-by-zero
+	}
+}
+
+func TestSummaryTimeInRange(t *testing.T) {
+	s := NewSummary(0.01)
+	for v := uint16(1); v <= 1000; v++ {
+		s.Insert(v)
+	}
+	got := s.TimeInRange(1, 500)
+	if got < 0.48 || got > 0.52 {
+		t.Errorf("TimeInRange(1, 500) == %v, want near 0.5", got)
+	}
+}
@@ -0,0 +1,244 @@
+package dexcom
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// defaultSubscribePageTypes is the set of page types Subscribe watches when
+// SubscribeOptions.PageTypes is left empty.
+var defaultSubscribePageTypes = []PageType{EGV_DATA, SENSOR_DATA, CAL_SET, INSERTION_TIME, METER_DATA}
+
+const defaultSubscribeInterval = 5 * time.Minute
+
+// pageReader is the subset of *Device that Subscribe polls. It exists so
+// the polling and dedup logic can be exercised with a fake in tests without
+// standing up a real device transport.
+type pageReader interface {
+	ReadDatabasePageRange(pt PageType) (first, last int, err error)
+	ReadDatabasePages(pt PageType, first, count int) ([][]byte, error)
+}
+
+// Cursor tracks, per PageType, the last database page number read and the
+// last-seen Timestamp.SystemTime, so that a restarted Subscribe call
+// resumes from where it left off instead of re-reading the device's entire
+// history on every poll. A zero Cursor starts from the beginning of the
+// device's history; Cursor is safe to persist (e.g. as JSON) and reload
+// across restarts.
+type Cursor struct {
+	LastPage       map[PageType]int
+	LastSystemTime map[PageType]time.Time
+}
+
+// NewCursor returns an empty Cursor.
+func NewCursor() *Cursor {
+	return &Cursor{
+		LastPage:       make(map[PageType]int),
+		LastSystemTime: make(map[PageType]time.Time),
+	}
+}
+
+// SubscribeOptions configures Subscribe.
+type SubscribeOptions struct {
+	// PageTypes restricts which page types are polled. If empty, Subscribe
+	// watches EGV, sensor, calibration, insertion, and meter pages.
+	PageTypes []PageType
+
+	// Interval is how often each page type is polled. Defaults to 5
+	// minutes, matching the receiver's sample rate.
+	Interval time.Duration
+
+	// Backoff returns how long to wait before retrying a page type after
+	// the given number of consecutive transport errors. Defaults to
+	// min(attempt*Interval, time.Hour).
+	Backoff func(attempt int) time.Duration
+
+	// Cursor is the resume point to continue from. If nil, Subscribe
+	// starts a fresh Cursor and emits the device's entire history.
+	Cursor *Cursor
+}
+
+func (o *SubscribeOptions) pageTypes() []PageType {
+	if len(o.PageTypes) > 0 {
+		return o.PageTypes
+	}
+	return defaultSubscribePageTypes
+}
+
+func (o *SubscribeOptions) interval() time.Duration {
+	if o.Interval > 0 {
+		return o.Interval
+	}
+	return defaultSubscribeInterval
+}
+
+func (o *SubscribeOptions) backoff(attempt int) time.Duration {
+	if o.Backoff != nil {
+		return o.Backoff(attempt)
+	}
+	d := time.Duration(attempt) * o.interval()
+	if d > time.Hour {
+		d = time.Hour
+	}
+	return d
+}
+
+// Subscribe polls the device's database pages on a fixed interval and
+// streams newly observed records, in chronological order, on the returned
+// channel. It deduplicates against opts.Cursor so that records already
+// delivered (in this call or a prior one, if the cursor is persisted and
+// reloaded) are not emitted again. Transport errors on a page type, and
+// malformed individual pages, are sent on the error channel and do not
+// abort the subscription; the error channel holds one unreceived error, and
+// further errors are dropped while it is full. Both channels are closed when
+// ctx is canceled.
+func (d *Device) Subscribe(ctx context.Context, opts SubscribeOptions) (<-chan *Record, <-chan error) {
+	return subscribe(ctx, d, opts)
+}
+
+func subscribe(ctx context.Context, pr pageReader, opts SubscribeOptions) (<-chan *Record, <-chan error) {
+	records := make(chan *Record)
+	errs := make(chan error, 1)
+	cursor := opts.Cursor
+	if cursor == nil {
+		cursor = NewCursor()
+	}
+	if cursor.LastPage == nil {
+		cursor.LastPage = make(map[PageType]int)
+	}
+	if cursor.LastSystemTime == nil {
+		cursor.LastSystemTime = make(map[PageType]time.Time)
+	}
+	pageTypes := opts.pageTypes()
+	interval := opts.interval()
+
+	go func() {
+		defer close(records)
+		defer close(errs)
+
+		sendErr := func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}
+
+		attempts := make(map[PageType]int, len(pageTypes))
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			var pending []*Record
+			for _, pt := range pageTypes {
+				new, pageErrs, err := pollPage(pr, pt, cursor)
+				for _, pageErr := range pageErrs {
+					sendErr(pageErr)
+				}
+				if err != nil {
+					attempts[pt]++
+					sendErr(err)
+					select {
+					case <-time.After(opts.backoff(attempts[pt])):
+					case <-ctx.Done():
+						return
+					}
+					continue
+				}
+				attempts[pt] = 0
+				pending = append(pending, new...)
+			}
+
+			sortByTime(pending)
+			for _, rec := range pending {
+				select {
+				case records <- rec:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return records, errs
+}
+
+// pollPage reads pages of type pt that haven't been read by cursor yet and
+// advances the cursor past them. It resumes from cursor's last-read page
+// number rather than the device's oldest available page, so a long-running
+// subscription doesn't re-fetch the whole history on every poll. Pages that
+// fail to unmarshal are reported in pageErrs rather than silently dropped;
+// err is reserved for transport-level failures reading the page
+// range/contents.
+func pollPage(pr pageReader, pt PageType, cursor *Cursor) (recs []*Record, pageErrs []error, err error) {
+	first, last, err := pr.ReadDatabasePageRange(pt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if last < first {
+		return nil, nil, nil
+	}
+
+	start := first
+	if lastRead, ok := cursor.LastPage[pt]; ok && lastRead+1 > start {
+		start = lastRead + 1
+	}
+	if start > last {
+		return nil, nil, nil
+	}
+
+	raw, err := pr.ReadDatabasePages(pt, start, last-start+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var decoded []*Record
+	for _, page := range raw {
+		rec := &Record{}
+		if err := rec.Unmarshal(pt, page); err != nil {
+			pageErrs = append(pageErrs, fmt.Errorf("subscribe: %v page: %w", pt, err))
+			continue
+		}
+		decoded = append(decoded, rec)
+	}
+
+	recs = newSince(decoded, cursor.LastSystemTime[pt])
+	advanceCursor(cursor, pt, recs)
+	cursor.LastPage[pt] = last
+	return recs, pageErrs, nil
+}
+
+// newSince returns the records whose Timestamp.SystemTime is after since.
+func newSince(records []*Record, since time.Time) []*Record {
+	var out []*Record
+	for _, rec := range records {
+		if rec.Timestamp.SystemTime.After(since) {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+// advanceCursor moves cursor's LastSystemTime for pt forward to the latest
+// SystemTime among recs, if any is newer than what's already recorded.
+func advanceCursor(cursor *Cursor, pt PageType, recs []*Record) {
+	for _, rec := range recs {
+		if rec.Timestamp.SystemTime.After(cursor.LastSystemTime[pt]) {
+			cursor.LastSystemTime[pt] = rec.Timestamp.SystemTime
+		}
+	}
+}
+
+// sortByTime orders records chronologically by Record.Time.
+func sortByTime(records []*Record) {
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Time().Before(records[j].Time())
+	})
+}
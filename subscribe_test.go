@@ -0,0 +1,153 @@
+package dexcom
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func recordAt(t time.Time) *Record {
+	return &Record{Timestamp: Timestamp{SystemTime: t, DisplayTime: t}}
+}
+
+func TestNewSince(t *testing.T) {
+	base := time.Unix(1000, 0)
+	records := []*Record{
+		recordAt(base),
+		recordAt(base.Add(time.Minute)),
+		recordAt(base.Add(-time.Minute)),
+	}
+	got := newSince(records, base)
+	if len(got) != 1 || !got[0].Timestamp.SystemTime.Equal(base.Add(time.Minute)) {
+		t.Fatalf("newSince(records, base) = %v, want only the record after base", got)
+	}
+}
+
+func TestAdvanceCursor(t *testing.T) {
+	cursor := NewCursor()
+	base := time.Unix(2000, 0)
+	advanceCursor(cursor, EGV_DATA, []*Record{
+		recordAt(base),
+		recordAt(base.Add(2 * time.Minute)),
+		recordAt(base.Add(time.Minute)),
+	})
+	want := base.Add(2 * time.Minute)
+	if got := cursor.LastSystemTime[EGV_DATA]; !got.Equal(want) {
+		t.Errorf("LastSystemTime[EGV_DATA] == %v, want %v", got, want)
+	}
+}
+
+func TestSortByTime(t *testing.T) {
+	base := time.Unix(3000, 0)
+	records := []*Record{
+		recordAt(base.Add(2 * time.Minute)),
+		recordAt(base),
+		recordAt(base.Add(time.Minute)),
+	}
+	sortByTime(records)
+	for i := 1; i < len(records); i++ {
+		if records[i].Time().Before(records[i-1].Time()) {
+			t.Fatalf("sortByTime did not order records: %v", records)
+		}
+	}
+}
+
+// fakePageReader implements pageReader with pages supplied per PageType,
+// for exercising Subscribe without a real device transport.
+type fakePageReader struct {
+	pages map[PageType][][]byte
+	err   map[PageType]error
+
+	reads []readCall
+}
+
+type readCall struct {
+	pt          PageType
+	first, last int
+}
+
+func (f *fakePageReader) ReadDatabasePageRange(pt PageType) (int, int, error) {
+	if err := f.err[pt]; err != nil {
+		return 0, 0, err
+	}
+	pages := f.pages[pt]
+	if len(pages) == 0 {
+		return 0, -1, nil
+	}
+	return 0, len(pages) - 1, nil
+}
+
+func (f *fakePageReader) ReadDatabasePages(pt PageType, first, count int) ([][]byte, error) {
+	f.reads = append(f.reads, readCall{pt: pt, first: first, last: first + count - 1})
+	return f.pages[pt][first : first+count], nil
+}
+
+func TestPollPageResumesFromCursor(t *testing.T) {
+	pr := &fakePageReader{
+		pages: map[PageType][][]byte{
+			EGV_DATA: {
+				make([]byte, 11),
+				make([]byte, 11),
+				make([]byte, 11),
+			},
+		},
+	}
+	cursor := NewCursor()
+
+	if _, _, err := pollPage(pr, EGV_DATA, cursor); err != nil {
+		t.Fatalf("first pollPage: %v", err)
+	}
+	if got := cursor.LastPage[EGV_DATA]; got != 2 {
+		t.Fatalf("LastPage[EGV_DATA] after first poll == %d, want 2", got)
+	}
+
+	// A second poll with no new pages on the device must not re-read
+	// anything already seen.
+	if _, _, err := pollPage(pr, EGV_DATA, cursor); err != nil {
+		t.Fatalf("second pollPage: %v", err)
+	}
+
+	pr.pages[EGV_DATA] = append(pr.pages[EGV_DATA], make([]byte, 11))
+	if _, _, err := pollPage(pr, EGV_DATA, cursor); err != nil {
+		t.Fatalf("third pollPage: %v", err)
+	}
+
+	want := []readCall{
+		{pt: EGV_DATA, first: 0, last: 2},
+		{pt: EGV_DATA, first: 3, last: 3},
+	}
+	if len(pr.reads) != len(want) {
+		t.Fatalf("ReadDatabasePages called %d times, want %d: %v", len(pr.reads), len(want), pr.reads)
+	}
+	for i, call := range pr.reads {
+		if call != want[i] {
+			t.Errorf("read %d == %+v, want %+v", i, call, want[i])
+		}
+	}
+}
+
+func TestSubscribeReportsMalformedPage(t *testing.T) {
+	pr := &fakePageReader{
+		pages: map[PageType][][]byte{
+			EGV_DATA: {make([]byte, 3)}, // wrong length for an EGV_DATA record
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	records, errs := subscribe(ctx, pr, SubscribeOptions{
+		PageTypes: []PageType{EGV_DATA},
+		Interval:  time.Millisecond,
+	})
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("expected a non-nil error for the malformed page")
+		}
+	case <-records:
+		t.Fatal("expected an error before any record, got a record instead")
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the malformed-page error")
+	}
+}